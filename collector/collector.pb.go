@@ -0,0 +1,178 @@
+// Package collector provides the protobuf messages and gRPC client/server
+// bindings for the Collector service defined in collector.proto.
+//
+// Hand-written rather than generated: this tree doesn't carry a pinned
+// protoc/protoc-gen-go toolchain, so there's nothing to regenerate this
+// file from reproducibly. Keep it in sync with collector.proto by hand
+// whenever the schema changes.
+package collector
+
+import (
+	context "context"
+
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Record carries a single encoded protobuf message produced by a LayerEncoder
+type Record struct {
+	Type      int32  `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	Timestamp int64  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Data      []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *Record) Reset()         { *m = Record{} }
+func (m *Record) String() string { return proto.CompactTextString(m) }
+func (*Record) ProtoMessage()    {}
+
+func (m *Record) GetType() int32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *Record) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Record) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// Ack confirms receipt of a Record
+type Ack struct {
+	Timestamp int64 `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (m *Ack) Reset()         { *m = Ack{} }
+func (m *Ack) String() string { return proto.CompactTextString(m) }
+func (*Ack) ProtoMessage()    {}
+
+func (m *Ack) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Record)(nil), "collector.Record")
+	proto.RegisterType((*Ack)(nil), "collector.Ack")
+}
+
+// CollectorClient is the client API for the Collector service
+type CollectorClient interface {
+	// Ingest accepts a stream of Records and acknowledges each one, so the
+	// sender can apply backpressure via the stream's flow control.
+	Ingest(ctx context.Context, opts ...grpc.CallOption) (Collector_IngestClient, error)
+}
+
+type collectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewCollectorClient returns a CollectorClient backed by cc
+func NewCollectorClient(cc grpc.ClientConnInterface) CollectorClient {
+	return &collectorClient{cc}
+}
+
+func (c *collectorClient) Ingest(ctx context.Context, opts ...grpc.CallOption) (Collector_IngestClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Collector_serviceDesc.Streams[0], "/collector.Collector/Ingest", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &collectorIngestClient{stream}, nil
+}
+
+// Collector_IngestClient is the bidi stream handle returned by Ingest
+type Collector_IngestClient interface {
+	Send(*Record) error
+	Recv() (*Ack, error)
+	grpc.ClientStream
+}
+
+type collectorIngestClient struct {
+	grpc.ClientStream
+}
+
+func (x *collectorIngestClient) Send(m *Record) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *collectorIngestClient) Recv() (*Ack, error) {
+	m := new(Ack)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CollectorServer is the server API for the Collector service
+type CollectorServer interface {
+	// Ingest accepts a stream of Records and acknowledges each one, so the
+	// sender can apply backpressure via the stream's flow control.
+	Ingest(Collector_IngestServer) error
+}
+
+// UnimplementedCollectorServer can be embedded to have forward compatible implementations
+type UnimplementedCollectorServer struct{}
+
+func (*UnimplementedCollectorServer) Ingest(Collector_IngestServer) error {
+	return status.Errorf(codes.Unimplemented, "method Ingest not implemented")
+}
+
+// RegisterCollectorServer registers srv as the implementation of the Collector service on s
+func RegisterCollectorServer(s *grpc.Server, srv CollectorServer) {
+	s.RegisterService(&_Collector_serviceDesc, srv)
+}
+
+func _Collector_Ingest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CollectorServer).Ingest(&collectorIngestServer{stream})
+}
+
+// Collector_IngestServer is the bidi stream handle passed to CollectorServer.Ingest
+type Collector_IngestServer interface {
+	Send(*Ack) error
+	Recv() (*Record, error)
+	grpc.ServerStream
+}
+
+type collectorIngestServer struct {
+	grpc.ServerStream
+}
+
+func (x *collectorIngestServer) Send(m *Ack) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *collectorIngestServer) Recv() (*Record, error) {
+	m := new(Record)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Collector_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "collector.Collector",
+	HandlerType: (*CollectorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Ingest",
+			Handler:       _Collector_Ingest_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "collector/collector.proto",
+}
@@ -0,0 +1,153 @@
+/*
+ * NETCAP - Network Capture Toolkit
+ * Copyright (c) 2017 Philipp Mieden <dreadl0ck [at] protonmail [dot] ch>
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package encoder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec abstracts over the compression algorithm used when persisting
+// encoded records, so LayerEncoder can write gzip or zstd streams (or none)
+// interchangeably instead of hard-coding compress/gzip.
+type Codec interface {
+
+	// NewWriter wraps w with the codec's compressing io.WriteCloser
+	NewWriter(w io.Writer) io.WriteCloser
+
+	// NewReader wraps r with the codec's decompressing io.ReadCloser
+	NewReader(r io.Reader) (io.ReadCloser, error)
+
+	// Name returns the codec identifier used in Config.Compression
+	Name() string
+
+	// Ext returns the file extension suffix appended after the base extension,
+	// e.g. ".gz" or ".zst"
+	Ext() string
+
+	// Magic returns the leading byte sequence readers can sniff to detect
+	// this codec, or nil if the codec produces no identifiable header
+	Magic() []byte
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) NewWriter(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+func (gzipCodec) Name() string                         { return "gzip" }
+func (gzipCodec) Ext() string                           { return ".gz" }
+func (gzipCodec) Magic() []byte                         { return []byte{0x1f, 0x8b} }
+
+type zstdCodec struct{}
+
+func (zstdCodec) NewWriter(w io.Writer) io.WriteCloser {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		// NewWriter only fails when handed invalid options, which we never pass
+		panic(err)
+	}
+	return zw
+}
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return zr.IOReadCloser(), nil
+}
+func (zstdCodec) Name() string { return "zstd" }
+func (zstdCodec) Ext() string  { return ".zst" }
+func (zstdCodec) Magic() []byte {
+	return []byte{0x28, 0xb5, 0x2f, 0xfd}
+}
+
+type noneCodec struct{}
+
+func (noneCodec) NewWriter(w io.Writer) io.WriteCloser         { return nopWriteCloser{w} }
+func (noneCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return ioutil.NopCloser(r), nil }
+func (noneCodec) Name() string                                 { return "none" }
+func (noneCodec) Ext() string                                  { return "" }
+func (noneCodec) Magic() []byte                                { return nil }
+
+// nopWriteCloser adapts an io.Writer to io.WriteCloser for the uncompressed codec
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// codecs maps Config.Compression identifiers to their Codec implementation
+var codecs = map[string]Codec{
+	"gzip": gzipCodec{},
+	"zstd": zstdCodec{},
+	"none": noneCodec{},
+}
+
+// codecsByMagic holds codecs for magic-byte sniffing on the reader side
+var codecsByMagic = []Codec{zstdCodec{}, gzipCodec{}}
+
+// lookupCodec returns the Codec registered for name. An empty name is
+// Config.Compression's zero value and stays backwards compatible with the
+// previous boolean field, which defaulted to no compression; any other
+// unrecognized name is a config typo and fails fast, mirroring invalidProto's
+// handling of unknown encoder names.
+func lookupCodec(name string) Codec {
+	if name == "" {
+		return noneCodec{}
+	}
+	if c, ok := codecs[name]; ok {
+		return c
+	}
+	invalidCodec(name)
+	return nil
+}
+
+// invalidCodec prints the available compression codecs and exits, matching
+// invalidProto's fail-fast behavior for unrecognized Config values.
+func invalidCodec(name string) {
+	fmt.Println("invalid compression codec", name)
+	fmt.Println("available codecs:")
+	for n := range codecs {
+		fmt.Println("-", n)
+	}
+	os.Exit(1)
+}
+
+// SniffCodec inspects the leading bytes of a stream and returns the Codec
+// that produced it, allowing readers to transparently decode gzip or zstd
+// archives without being told the codec up front.
+func SniffCodec(header []byte) Codec {
+	for _, c := range codecsByMagic {
+		magic := c.Magic()
+		if len(header) >= len(magic) && bytes.Equal(header[:len(magic)], magic) {
+			return c
+		}
+	}
+	return noneCodec{}
+}
+
+// closeCodecWriter flushes and closes a codec's writer, mirroring the
+// panic-on-error convention of CloseGzipWriters and friends in file.go.
+func closeCodecWriter(w io.WriteCloser) {
+	if w == nil {
+		return
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ * NETCAP - Network Capture Toolkit
+ * Copyright (c) 2017 Philipp Mieden <dreadl0ck [at] protonmail [dot] ch>
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package encoder
+
+// Config bundles the settings InitLayerEncoders needs to select which
+// encoders to run and how each one should persist its records.
+type Config struct {
+	// IncludeEncoders and ExcludeEncoders are comma-separated layer names;
+	// at most one of them should be set
+	IncludeEncoders string
+	ExcludeEncoders string
+
+	// Buffer wraps each encoder's output file in a buffered writer
+	Buffer bool
+
+	// Compression selects the codec applied to .ncap/.csv output: "gzip",
+	// "zstd" or "none"/"" (no compression, the default)
+	Compression string
+
+	// CSV writes records as CSV instead of delimited protobuf
+	CSV bool
+
+	// Out is the directory encoders write their output files into
+	Out string
+
+	// WriteChan makes LayerEncoder.GetChan available instead of writing to
+	// a file; buffering, compression and Seekable cannot be combined with it
+	WriteChan bool
+
+	// Seekable writes the .ncap output as a chunked archive with a TOC
+	// footer (see SeekableWriter) instead of a plain streaming file
+	Seekable bool
+
+	// ChunkSize is the uncompressed bytes buffered per chunk when Seekable
+	// is set; <= 0 uses DefaultChunkSize
+	ChunkSize int
+
+	// GRPCEndpoint, when set, streams records to a netcap.Collector gRPC
+	// service instead of writing them to a file, taking precedence over
+	// Buffer, Compression, CSV, Out, Seekable and ChunkSize
+	GRPCEndpoint string
+}
@@ -0,0 +1,192 @@
+/*
+ * NETCAP - Network Capture Toolkit
+ * Copyright (c) 2017 Philipp Mieden <dreadl0ck [at] protonmail [dot] ch>
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package encoder
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dreadl0ck/netcap/collector"
+	"github.com/dreadl0ck/netcap/types"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+)
+
+const (
+	// initialReconnectBackoff is the delay before the first retry of a
+	// broken Ingest stream; doubled after each further failed attempt
+	initialReconnectBackoff = 500 * time.Millisecond
+
+	// maxReconnectBackoff caps the exponential backoff between stream
+	// reconnect attempts
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// grpcSink streams a LayerEncoder's records to a netcap.Collector gRPC
+// service over a bidi stream instead of writing them to a local .ncap/.csv
+// file, enabling distributed capture where sensors forward decoded records
+// to a central analyzer without a shared filesystem.
+type grpcSink struct {
+	typ  types.Type
+	conn *grpc.ClientConn
+
+	mu     sync.Mutex
+	stream collector.Collector_IngestClient
+
+	// closed is closed by Close to abort an in-flight reconnect loop
+	// without having to wait on mu, which reconnect holds for its duration
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// newGRPCSink dials endpoint and opens the Ingest stream used to push
+// records of the given encoder type. The underlying ClientConn reconnects
+// transport-level failures with exponential backoff on its own, but gRPC
+// never resumes a broken streaming RPC once one side returns an error, so
+// Send and drainAcks additionally re-establish the Ingest stream itself
+// (also with exponential backoff) whenever they observe a dead stream.
+func newGRPCSink(endpoint string, typ types.Type) (*grpcSink, error) {
+	conn, err := grpc.Dial(
+		endpoint,
+		grpc.WithInsecure(),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.DefaultConfig}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &grpcSink{typ: typ, conn: conn, closed: make(chan struct{})}
+
+	stream, err := g.openStream()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	g.stream = stream
+
+	// drain Acks so the stream's flow control keeps applying backpressure
+	// without the sink having to buffer them itself
+	go g.drainAcks(stream)
+
+	return g, nil
+}
+
+// openStream opens a fresh Ingest call on the sink's ClientConn
+func (g *grpcSink) openStream() (collector.Collector_IngestClient, error) {
+	return collector.NewCollectorClient(g.conn).Ingest(context.Background())
+}
+
+// reconnect retries openStream with exponential backoff until it succeeds
+// or g.closed is closed by Close, which must be able to abort this loop
+// without taking g.mu (handleStreamFailure holds it for the whole call).
+func (g *grpcSink) reconnect() collector.Collector_IngestClient {
+	delay := initialReconnectBackoff
+
+	for {
+		select {
+		case <-g.closed:
+			return nil
+		default:
+		}
+
+		stream, err := g.openStream()
+		if err == nil {
+			go g.drainAcks(stream)
+			return stream
+		}
+
+		select {
+		case <-g.closed:
+			return nil
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxReconnectBackoff {
+			delay = maxReconnectBackoff
+		}
+	}
+}
+
+// handleStreamFailure reconnects the sink's active stream if dead is still
+// the one in use, guarding against Send and drainAcks racing to reconnect
+// the same failure, and returns whichever stream ends up active.
+func (g *grpcSink) handleStreamFailure(dead collector.Collector_IngestClient) collector.Collector_IngestClient {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stream == dead {
+		g.stream = g.reconnect()
+	}
+	return g.stream
+}
+
+func (g *grpcSink) drainAcks(stream collector.Collector_IngestClient) {
+	for {
+		if _, err := stream.Recv(); err != nil {
+			g.handleStreamFailure(stream)
+			return
+		}
+	}
+}
+
+// Send marshals msg and pushes it as a Record over the stream, reconnecting
+// and retrying once if the stream has gone stale since the last call.
+func (g *grpcSink) Send(msg proto.Message, timestamp time.Time) error {
+	raw, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	rec := &collector.Record{
+		Type:      int32(g.typ),
+		Timestamp: timestamp.UnixNano(),
+		Data:      raw,
+	}
+
+	g.mu.Lock()
+	stream := g.stream
+	g.mu.Unlock()
+
+	if err := stream.Send(rec); err != nil {
+		stream = g.handleStreamFailure(stream)
+		if stream == nil {
+			return err
+		}
+		return stream.Send(rec)
+	}
+	return nil
+}
+
+// Close aborts any in-flight reconnect, closes the send direction of the
+// stream and tears down the connection.
+func (g *grpcSink) Close() error {
+	// signal before taking g.mu: a reconnect loop holds g.mu for as long as
+	// it's retrying, so this must not block on the lock or a sink stuck
+	// reconnecting against a dead collector could never be torn down
+	g.closeOnce.Do(func() { close(g.closed) })
+
+	g.mu.Lock()
+	stream := g.stream
+	g.mu.Unlock()
+
+	if stream != nil {
+		if err := stream.CloseSend(); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return g.conn.Close()
+}
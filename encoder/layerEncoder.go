@@ -15,8 +15,9 @@ package encoder
 
 import (
 	"bufio"
-	"compress/gzip"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -82,19 +83,23 @@ type (
 		Type  types.Type
 
 		// Private
-		file      *os.File
-		bWriter   *bufio.Writer
-		gWriter   *gzip.Writer
-		dWriter   *delimited.Writer
-		aWriter   *AtomicDelimitedWriter
-		Handler   LayerEncoderHandler
-		cWriter   *chanWriter
-		csvWriter *csvWriter
+		file        *os.File
+		bWriter     *bufio.Writer
+		codec       Codec
+		codecWriter io.WriteCloser
+		dWriter     *delimited.Writer
+		aWriter     *AtomicDelimitedWriter
+		sWriter     *SeekableWriter
+		grpc        *grpcSink
+		Handler     LayerEncoderHandler
+		cWriter     *chanWriter
+		csvWriter   *csvWriter
 
 		// Config
 		compress bool
 		csv      bool
 		buffer   bool
+		seekable bool
 		out      string
 	}
 )
@@ -164,7 +169,7 @@ func InitLayerEncoders(c Config) {
 	for _, e := range layerEncoderSlice {
 
 		// fmt.Println("init", d.layer)
-		e.Init(c.Buffer, c.Compression, c.CSV, c.Out, c.WriteChan)
+		e.Init(c.Buffer, c.Compression, c.CSV, c.Out, c.WriteChan, c.Seekable, c.ChunkSize, c.GRPCEndpoint)
 
 		// write header
 		if e.csv {
@@ -173,7 +178,7 @@ func InitLayerEncoders(c Config) {
 				panic(err)
 			}
 		} else {
-			err := e.aWriter.PutProto(NewHeader(e.Type, c))
+			err := e.putProto(NewHeader(e.Type, c), time.Now())
 			if err != nil {
 				fmt.Println("failed to write header")
 				panic(err)
@@ -213,46 +218,84 @@ func (d *LayerEncoder) Encode(l gopacket.Layer, timestamp time.Time) error {
 				return err
 			}
 		} else {
-			err := d.aWriter.PutProto(decoded)
-			if err != nil {
-				return err
-			}
+			return d.putProto(decoded, timestamp)
 		}
 	}
 	return nil
 }
 
-// Init initializes and configures the encoder
-func (d *LayerEncoder) Init(buffer, compress, csv bool, out string, writeChan bool) {
+// putProto serializes and writes a single protobuf record: to the gRPC sink
+// when the encoder was configured with a collector endpoint, otherwise into
+// the current SeekableWriter chunk for the seekable chunked format, or
+// through the regular delimited/atomic writer.
+func (d *LayerEncoder) putProto(msg proto.Message, timestamp time.Time) error {
+	if d.grpc != nil {
+		return d.grpc.Send(msg, timestamp)
+	}
+	if d.seekable {
+		raw, err := proto.Marshal(msg)
+		if err != nil {
+			return err
+		}
 
-	d.compress = compress
+		var buf bytes.Buffer
+		if err := delimited.NewWriter(&buf).Put(raw); err != nil {
+			return err
+		}
+		return d.sWriter.WriteRecord(buf.Bytes(), timestamp.UnixNano())
+	}
+	return d.aWriter.PutProto(msg)
+}
+
+// Init initializes and configures the encoder.
+// codecName selects the compression codec ("gzip", "zstd" or "none") used
+// for both the .ncap and .csv outputs; file extensions follow the codec.
+// When seekable is set, the output is written as a chunked .ncap.seek
+// archive with a TOC footer (see SeekableWriter) instead of a plain
+// streaming .ncap file, since the two are incompatible on-disk formats;
+// chunkSize controls the uncompressed bytes buffered per chunk (<= 0 uses
+// DefaultChunkSize). seekable has no effect on the csv output.
+// When grpcEndpoint is set, records are streamed to a netcap.Collector gRPC
+// service instead of being written to a file, taking precedence over
+// buffer, codecName, csv, out, seekable and chunkSize.
+func (d *LayerEncoder) Init(buffer bool, codecName string, csv bool, out string, writeChan bool, seekable bool, chunkSize int, grpcEndpoint string) {
+
+	d.codec = lookupCodec(codecName)
+	d.compress = d.codec.Name() != "none"
 	d.buffer = buffer
-	d.csv = csv
 	d.out = out
 
+	if grpcEndpoint != "" {
+		g, err := newGRPCSink(grpcEndpoint, d.Type)
+		if err != nil {
+			panic(err)
+		}
+		d.grpc = g
+		return
+	}
+
+	d.csv = csv
+	d.seekable = seekable && !csv
+
 	if csv {
 
 		// create file
-		if compress {
-			d.file = CreateFile(filepath.Join(out, d.Layer.String()), ".csv.gz")
-		} else {
-			d.file = CreateFile(filepath.Join(out, d.Layer.String()), ".csv")
-		}
+		d.file = CreateFile(filepath.Join(out, d.Layer.String()), ".csv"+d.codec.Ext())
 
 		if buffer {
 
 			d.bWriter = bufio.NewWriterSize(d.file, BlockSize)
 
-			if compress {
-				d.gWriter = gzip.NewWriter(d.bWriter)
-				d.csvWriter = NewCSVWriter(d.gWriter)
+			if d.compress {
+				d.codecWriter = d.codec.NewWriter(d.bWriter)
+				d.csvWriter = NewCSVWriter(d.codecWriter)
 			} else {
 				d.csvWriter = NewCSVWriter(d.bWriter)
 			}
 		} else {
-			if compress {
-				d.gWriter = gzip.NewWriter(d.file)
-				d.csvWriter = NewCSVWriter(d.gWriter)
+			if d.compress {
+				d.codecWriter = d.codec.NewWriter(d.file)
+				d.csvWriter = NewCSVWriter(d.codecWriter)
 			} else {
 				d.csvWriter = NewCSVWriter(d.file)
 			}
@@ -260,19 +303,33 @@ func (d *LayerEncoder) Init(buffer, compress, csv bool, out string, writeChan bo
 		return
 	}
 
-	if writeChan && buffer || writeChan && compress {
-		panic("buffering or compression cannot be activated when running using writeChan")
+	if writeChan && (buffer || d.compress || seekable) {
+		panic("buffering, compression and the seekable format cannot be activated when running using writeChan")
 	}
 
 	// write into channel OR into file
 	if writeChan {
 		d.cWriter = newChanWriter()
+	} else if seekable {
+		// the seekable archive is a different on-disk format than the legacy
+		// streaming .ncap(.gz) file (chunked + TOC footer vs. one continuous
+		// delimited stream), so it gets its own extension rather than
+		// colliding with the legacy name
+		d.file = CreateFile(filepath.Join(out, d.Layer.String()), ".ncap.seek"+d.codec.Ext())
 	} else {
-		if compress {
-			d.file = CreateFile(filepath.Join(out, d.Layer.String()), ".ncap.gz")
+		d.file = CreateFile(filepath.Join(out, d.Layer.String()), ".ncap"+d.codec.Ext())
+	}
+
+	if seekable {
+		// the seekable archive compresses each chunk itself, so the codec is
+		// applied per-chunk by SeekableWriter rather than to the whole stream
+		if buffer {
+			d.bWriter = bufio.NewWriterSize(d.file, BlockSize)
+			d.sWriter = NewSeekableWriter(d.bWriter, d.codec, chunkSize)
 		} else {
-			d.file = CreateFile(filepath.Join(out, d.Layer.String()), ".ncap")
+			d.sWriter = NewSeekableWriter(d.file, d.codec, chunkSize)
 		}
+		return
 	}
 
 	// buffer data?
@@ -280,16 +337,16 @@ func (d *LayerEncoder) Init(buffer, compress, csv bool, out string, writeChan bo
 	if buffer {
 
 		d.bWriter = bufio.NewWriterSize(d.file, BlockSize)
-		if compress {
-			d.gWriter = gzip.NewWriter(d.bWriter)
-			d.dWriter = delimited.NewWriter(d.gWriter)
+		if d.compress {
+			d.codecWriter = d.codec.NewWriter(d.bWriter)
+			d.dWriter = delimited.NewWriter(d.codecWriter)
 		} else {
 			d.dWriter = delimited.NewWriter(d.bWriter)
 		}
 	} else {
-		if compress {
-			d.gWriter = gzip.NewWriter(d.file)
-			d.dWriter = delimited.NewWriter(d.gWriter)
+		if d.compress {
+			d.codecWriter = d.codec.NewWriter(d.file)
+			d.dWriter = delimited.NewWriter(d.codecWriter)
 		} else {
 			if writeChan {
 				// write into channel writer without compression
@@ -310,8 +367,18 @@ func (d *LayerEncoder) GetChan() <-chan []byte {
 
 // Destroy closes and flushes all writers
 func (d *LayerEncoder) Destroy() (name string, size int64) {
-	if d.compress {
-		CloseGzipWriters(d.gWriter)
+	if d.grpc != nil {
+		if err := d.grpc.Close(); err != nil {
+			panic(err)
+		}
+		return d.Layer.String(), 0
+	}
+	if d.seekable {
+		if err := d.sWriter.Close(); err != nil {
+			panic(err)
+		}
+	} else if d.compress {
+		closeCodecWriter(d.codecWriter)
 	}
 	if d.buffer {
 		FlushWriters(d.bWriter)
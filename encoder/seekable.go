@@ -0,0 +1,228 @@
+/*
+ * NETCAP - Network Capture Toolkit
+ * Copyright (c) 2017 Philipp Mieden <dreadl0ck [at] protonmail [dot] ch>
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package encoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+const (
+	// SeekableMagic identifies a seekable chunked .ncap archive and
+	// distinguishes it from a legacy streaming .ncap(.gz) file
+	SeekableMagic = "NCAPSEEK"
+
+	// SeekableVersion is the on-disk format version of the seekable archive
+	SeekableVersion uint32 = 1
+
+	// DefaultChunkSize is the default amount of uncompressed delimited
+	// record bytes buffered per chunk before it is compressed and flushed
+	DefaultChunkSize = 4 * 1024 * 1024
+
+	// FooterSize is the fixed size in bytes of the trailing Footer struct:
+	// tocOffset(8) + tocLength(8) + magic(8) + version(4)
+	FooterSize = 8 + 8 + 8 + 4
+)
+
+type (
+	// TOCEntry records where a chunk lives in the archive, so a SeekableReader
+	// can binary-search by timestamp or record index without inflating chunks
+	TOCEntry struct {
+		Offset          uint64 // file offset of the chunk's compressed codec frame
+		ChunkID         uint32
+		UncompressedLen uint64
+		CompressedLen   uint64
+		FirstTimestamp  int64
+		LastTimestamp   int64
+		RecordCount     uint32
+	}
+
+	// Footer is the fixed-size trailer written after the TOC, letting a
+	// reader locate the table of contents without scanning the whole file
+	Footer struct {
+		TOCOffset uint64
+		TOCLength uint64
+		Magic     [8]byte
+		Version   uint32
+	}
+
+	// SeekableWriter buffers delimited protobuf records into fixed-size
+	// chunks, compresses each chunk independently with the configured Codec
+	// and appends a TOC + Footer on Close so consumers can jump straight to
+	// a packet range or timestamp without streaming the whole file. Chunk
+	// metadata lives only in the TOC: the chunk bodies themselves are
+	// written back-to-back with no interleaved header, so the concatenated
+	// stream is just a sequence of codec frames a naive gzip/zstd reader can
+	// decode sequentially, the same as a legacy streaming .ncap(.gz) file.
+	// WriteRecord and Close are safe for concurrent use, like
+	// AtomicDelimitedWriter.
+	SeekableWriter struct {
+		mu        sync.Mutex
+		w         io.Writer
+		codec     Codec
+		chunkSize int
+		offset    uint64
+
+		buf         bytes.Buffer
+		chunkID     uint32
+		recordCount uint32
+		first, last int64
+
+		toc []TOCEntry
+	}
+)
+
+// NewSeekableWriter returns a SeekableWriter that writes chunked, codec
+// compressed frames to w, buffering up to chunkSize uncompressed bytes per
+// chunk. A chunkSize <= 0 falls back to DefaultChunkSize.
+func NewSeekableWriter(w io.Writer, codec Codec, chunkSize int) *SeekableWriter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &SeekableWriter{
+		w:         w,
+		codec:     codec,
+		chunkSize: chunkSize,
+	}
+}
+
+// WriteRecord appends a single delimited protobuf record (varint length
+// prefix + payload, as produced by delimited.Writer) to the current chunk,
+// flushing the chunk first if appending it would exceed chunkSize. A record
+// is never split across two chunks. Safe for concurrent use.
+func (s *SeekableWriter) WriteRecord(data []byte, timestampNano int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.buf.Len() > 0 && s.buf.Len()+len(data) > s.chunkSize {
+		if err := s.flushChunk(); err != nil {
+			return err
+		}
+	}
+
+	if s.recordCount == 0 {
+		s.first = timestampNano
+	}
+	s.last = timestampNano
+	s.recordCount++
+
+	_, err := s.buf.Write(data)
+	return err
+}
+
+// flushChunk compresses the buffered records into a self-contained codec
+// stream and appends it to the underlying writer as-is; no header is
+// interleaved into the stream, so the chunk bodies written across calls
+// are a pure concatenation of codec frames. The chunk's metadata is
+// recorded in the in-memory TOC only, to be written out on Close.
+func (s *SeekableWriter) flushChunk() error {
+	if s.recordCount == 0 {
+		return nil
+	}
+
+	var compressed bytes.Buffer
+
+	cw := s.codec.NewWriter(&compressed)
+	if _, err := cw.Write(s.buf.Bytes()); err != nil {
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+
+	entry := TOCEntry{
+		Offset:          s.offset,
+		ChunkID:         s.chunkID,
+		UncompressedLen: uint64(s.buf.Len()),
+		CompressedLen:   uint64(compressed.Len()),
+		FirstTimestamp:  s.first,
+		LastTimestamp:   s.last,
+		RecordCount:     s.recordCount,
+	}
+
+	n, err := s.w.Write(compressed.Bytes())
+	if err != nil {
+		return err
+	}
+
+	s.offset += uint64(n)
+	s.toc = append(s.toc, entry)
+
+	s.chunkID++
+	s.recordCount = 0
+	s.first, s.last = 0, 0
+	s.buf.Reset()
+
+	return nil
+}
+
+// Close flushes any buffered records as a final chunk and appends the TOC
+// and Footer, completing the seekable archive. Safe for concurrent use.
+func (s *SeekableWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.flushChunk(); err != nil {
+		return err
+	}
+
+	tocOffset := s.offset
+	tocLen, err := writeTOC(s.w, s.toc)
+	if err != nil {
+		return err
+	}
+	s.offset += tocLen
+
+	var footer Footer
+	footer.TOCOffset = tocOffset
+	footer.TOCLength = tocLen
+	copy(footer.Magic[:], SeekableMagic)
+	footer.Version = SeekableVersion
+
+	return writeFooter(s.w, footer)
+}
+
+func writeTOC(w io.Writer, toc []TOCEntry) (uint64, error) {
+	var buf bytes.Buffer
+
+	if err := binary.Write(&buf, binary.BigEndian, uint32(len(toc))); err != nil {
+		return 0, err
+	}
+	for _, e := range toc {
+		fields := []interface{}{e.Offset, e.ChunkID, e.UncompressedLen, e.CompressedLen, e.FirstTimestamp, e.LastTimestamp, e.RecordCount}
+		for _, f := range fields {
+			if err := binary.Write(&buf, binary.BigEndian, f); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := w.Write(buf.Bytes())
+	return uint64(n), err
+}
+
+func writeFooter(w io.Writer, f Footer) error {
+	if err := binary.Write(w, binary.BigEndian, f.TOCOffset); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, f.TOCLength); err != nil {
+		return err
+	}
+	if _, err := w.Write(f.Magic[:]); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, f.Version)
+}
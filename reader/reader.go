@@ -0,0 +1,74 @@
+/*
+ * NETCAP - Network Capture Toolkit
+ * Copyright (c) 2017 Philipp Mieden <dreadl0ck [at] protonmail [dot] ch>
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package reader
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/dreadl0ck/netcap/encoder"
+	"github.com/google/kythe/kythe/go/platform/delimited"
+)
+
+// Reader streams delimited protobuf records out of a legacy streaming .ncap
+// archive, sniffing its codec from the leading magic bytes so callers don't
+// need to know up front whether the file is gzip-, zstd- or uncompressed.
+type Reader struct {
+	f  *os.File
+	rc io.ReadCloser
+	dr *delimited.Reader
+}
+
+// Open opens path, sniffs its codec and returns a Reader over its delimited
+// protobuf records. Use reader.OpenSeekable instead for chunked archives.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(f)
+
+	header, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		f.Close()
+		return nil, err
+	}
+
+	codec := encoder.SniffCodec(header)
+
+	rc, err := codec.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Reader{f: f, rc: rc, dr: delimited.NewReader(rc)}, nil
+}
+
+// Next returns the next delimited record's raw protobuf bytes, or io.EOF
+// once the archive is exhausted.
+func (r *Reader) Next() ([]byte, error) {
+	return r.dr.Next()
+}
+
+// Close releases the codec reader and the underlying file handle
+func (r *Reader) Close() error {
+	if err := r.rc.Close(); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
@@ -0,0 +1,207 @@
+/*
+ * NETCAP - Network Capture Toolkit
+ * Copyright (c) 2017 Philipp Mieden <dreadl0ck [at] protonmail [dot] ch>
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package reader provides read access to netcap's on-disk archive formats.
+package reader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dreadl0ck/netcap/encoder"
+	"github.com/edsrzf/mmap-go"
+)
+
+// ErrNotSeekable is returned when a file's footer magic does not identify
+// it as a seekable chunked archive produced by encoder.SeekableWriter
+var ErrNotSeekable = errors.New("reader: not a seekable netcap archive")
+
+// SeekableReader memory-maps a seekable .ncap archive's footer and TOC so
+// callers can jump directly to the chunk(s) covering a record index or
+// timestamp range, instead of streaming and decompressing the whole file.
+type SeekableReader struct {
+	f    *os.File
+	data mmap.MMap
+	toc  []encoder.TOCEntry
+}
+
+// OpenSeekable opens path and validates that it is a seekable archive by
+// reading and checking its trailing Footer.
+func OpenSeekable(path string) (*SeekableReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := mmap.Map(f, mmap.RDONLY, 0)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r := &SeekableReader{f: f, data: data}
+
+	footer, err := r.readFooter()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	toc, err := r.readTOC(footer)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	r.toc = toc
+
+	return r, nil
+}
+
+// Close unmaps the archive and closes the underlying file
+func (r *SeekableReader) Close() error {
+	if err := r.data.Unmap(); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
+
+// NumRecords returns the total number of delimited records in the archive
+func (r *SeekableReader) NumRecords() int {
+	var n int
+	for _, e := range r.toc {
+		n += int(e.RecordCount)
+	}
+	return n
+}
+
+// ChunkByIndex inflates and returns the raw delimited record bytes of the
+// chunk that contains the record at the given zero-based index across the
+// whole archive.
+func (r *SeekableReader) ChunkByIndex(index int) ([]byte, error) {
+	var seen int
+	for _, e := range r.toc {
+		if index < seen+int(e.RecordCount) {
+			return r.inflate(e)
+		}
+		seen += int(e.RecordCount)
+	}
+	return nil, fmt.Errorf("reader: record index %d out of range", index)
+}
+
+// ChunkByTimestamp inflates and returns the raw delimited record bytes of
+// the chunk whose [FirstTimestamp, LastTimestamp] range contains ts, using
+// a binary search over the TOC (which is ordered by capture time).
+func (r *SeekableReader) ChunkByTimestamp(ts time.Time) ([]byte, error) {
+	nano := ts.UnixNano()
+
+	i := sort.Search(len(r.toc), func(i int) bool {
+		return r.toc[i].LastTimestamp >= nano
+	})
+	if i == len(r.toc) || r.toc[i].FirstTimestamp > nano {
+		return nil, fmt.Errorf("reader: no chunk covers timestamp %s", ts)
+	}
+
+	return r.inflate(r.toc[i])
+}
+
+// TOC returns the archive's table of contents, ordered by chunk offset
+func (r *SeekableReader) TOC() []encoder.TOCEntry {
+	return r.toc
+}
+
+// inflate decompresses a chunk's body, sniffing the codec from its magic
+// bytes so gzip- and zstd-compressed chunks are both handled transparently.
+// Chunk bodies are written back-to-back with no interleaved header, so
+// e.Offset points directly at the codec frame recorded in the TOC.
+func (r *SeekableReader) inflate(e encoder.TOCEntry) ([]byte, error) {
+	start := e.Offset
+	end := start + e.CompressedLen
+	if end > uint64(len(r.data)) {
+		return nil, fmt.Errorf("reader: chunk %d extends past end of file", e.ChunkID)
+	}
+	body := r.data[start:end]
+
+	codec := encoder.SniffCodec(body)
+
+	zr, err := codec.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return ioutil.ReadAll(zr)
+}
+
+func (r *SeekableReader) readFooter() (encoder.Footer, error) {
+	var footer encoder.Footer
+
+	if len(r.data) < encoder.FooterSize {
+		return footer, ErrNotSeekable
+	}
+
+	tail := r.data[len(r.data)-encoder.FooterSize:]
+	buf := bytes.NewReader(tail)
+
+	if err := binary.Read(buf, binary.BigEndian, &footer.TOCOffset); err != nil {
+		return footer, err
+	}
+	if err := binary.Read(buf, binary.BigEndian, &footer.TOCLength); err != nil {
+		return footer, err
+	}
+	if _, err := buf.Read(footer.Magic[:]); err != nil {
+		return footer, err
+	}
+	if err := binary.Read(buf, binary.BigEndian, &footer.Version); err != nil {
+		return footer, err
+	}
+
+	if string(footer.Magic[:]) != encoder.SeekableMagic {
+		return footer, ErrNotSeekable
+	}
+
+	return footer, nil
+}
+
+func (r *SeekableReader) readTOC(footer encoder.Footer) ([]encoder.TOCEntry, error) {
+	start := footer.TOCOffset
+	end := start + footer.TOCLength
+	if end > uint64(len(r.data)) {
+		return nil, fmt.Errorf("reader: TOC extends past end of file")
+	}
+
+	buf := bytes.NewReader(r.data[start:end])
+
+	var count uint32
+	if err := binary.Read(buf, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+
+	toc := make([]encoder.TOCEntry, count)
+	for i := range toc {
+		e := &toc[i]
+		for _, field := range []interface{}{&e.Offset, &e.ChunkID, &e.UncompressedLen, &e.CompressedLen, &e.FirstTimestamp, &e.LastTimestamp, &e.RecordCount} {
+			if err := binary.Read(buf, binary.BigEndian, field); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return toc, nil
+}
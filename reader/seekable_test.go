@@ -0,0 +1,96 @@
+/*
+ * NETCAP - Network Capture Toolkit
+ * Copyright (c) 2017 Philipp Mieden <dreadl0ck [at] protonmail [dot] ch>
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package reader_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dreadl0ck/netcap/encoder"
+	"github.com/dreadl0ck/netcap/reader"
+	"github.com/google/kythe/kythe/go/platform/delimited"
+)
+
+// TestSeekableRoundTrip writes records across multiple small chunks with
+// SeekableWriter, reopens the archive with OpenSeekable and verifies the
+// TOC-backed accessors return the expected data.
+func TestSeekableRoundTrip(t *testing.T) {
+	const numRecords = 50
+
+	path := filepath.Join(t.TempDir(), "test.ncap.seek")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// use the "none" codec (returned by SniffCodec when nothing matches) so
+	// the test exercises the chunking/TOC logic without pulling in gzip/zstd
+	codec := encoder.SniffCodec(nil)
+
+	// a tiny chunk size forces many chunk boundaries across numRecords
+	w := encoder.NewSeekableWriter(f, codec, 32)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	for i := 0; i < numRecords; i++ {
+		var buf bytes.Buffer
+		if err := delimited.NewWriter(&buf).Put([]byte(fmt.Sprintf("record-%02d", i))); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteRecord(buf.Bytes(), base+int64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := reader.OpenSeekable(path)
+	if err != nil {
+		t.Fatalf("OpenSeekable: %v", err)
+	}
+	defer r.Close()
+
+	if got := r.NumRecords(); got != numRecords {
+		t.Errorf("NumRecords() = %d, want %d", got, numRecords)
+	}
+
+	if len(r.TOC()) < 2 {
+		t.Fatalf("expected multiple chunks for chunkSize 32, got %d", len(r.TOC()))
+	}
+
+	if _, err := r.ChunkByIndex(0); err != nil {
+		t.Errorf("ChunkByIndex(0): %v", err)
+	}
+	if _, err := r.ChunkByIndex(numRecords - 1); err != nil {
+		t.Errorf("ChunkByIndex(%d): %v", numRecords-1, err)
+	}
+	if _, err := r.ChunkByIndex(numRecords); err == nil {
+		t.Error("ChunkByIndex(numRecords) should be out of range")
+	}
+
+	if _, err := r.ChunkByTimestamp(time.Unix(0, base)); err != nil {
+		t.Errorf("ChunkByTimestamp(first): %v", err)
+	}
+	if _, err := r.ChunkByTimestamp(time.Unix(0, base+int64(numRecords-1))); err != nil {
+		t.Errorf("ChunkByTimestamp(last): %v", err)
+	}
+}